@@ -15,15 +15,17 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/gvallee/go_benchmark/pkg/benchmark"
 	"github.com/gvallee/go_hpc_jobmgr/pkg/implem"
 	"github.com/gvallee/go_software_build/pkg/app"
 	"github.com/gvallee/go_util/pkg/util"
 	"github.com/gvallee/openhpca/tools/internal/pkg/config"
-	"github.com/gvallee/openhpca/tools/internal/pkg/overlap"
+	"github.com/gvallee/openhpca/tools/internal/pkg/dashboard"
 	"github.com/gvallee/openhpca/tools/internal/pkg/result"
-	"github.com/gvallee/openhpca/tools/internal/pkg/smb"
+	"github.com/gvallee/openhpca/tools/internal/pkg/sandbox"
 	"github.com/gvallee/validation_tool/pkg/experiments"
 	"github.com/gvallee/validation_tool/pkg/platform"
 )
@@ -32,7 +34,7 @@ func getRunDir(cfg *config.Data) string {
 	return filepath.Join(cfg.WP.Basedir, "run")
 }
 
-func displayResults(cfg *config.Data) error {
+func displayResults(cfg *config.Data, commit, platformName string, ppn, nnodes int) error {
 	runDir := getRunDir(cfg)
 	resultsStr, err := result.String(runDir)
 	if err != nil {
@@ -44,24 +46,96 @@ func displayResults(cfg *config.Data) error {
 	if err != nil {
 		return err
 	}
+
+	metrics, err := result.Metrics(runDir)
+	if err != nil {
+		return err
+	}
+	if err := result.WriteBenchstat(filepath.Join(cfg.Basedir, "..", result.TxtFileName), metrics); err != nil {
+		return err
+	}
+	if err := result.WriteJSON(filepath.Join(cfg.Basedir, "..", result.JSONFileName), metrics, commit, platformName, ppn, nnodes); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func experimentIsStrictlyPointToPoint(name string) bool {
-	switch name {
-	case "osu_latency":
-		return true
-	case "osu_noncontig_mem_latency":
-		return true
-	case "osu_bw":
-		return true
-	case "osu_noncontig_mem_bw":
-		return true
-	case "smb_mpi_overhead":
-		return true
-	default:
-		return false
+// completedExperiments returns the set of experiment names that already have
+// results in runDir.
+func completedExperiments(runDir string) map[string]bool {
+	completed := make(map[string]bool)
+	metrics, err := result.Metrics(runDir)
+	if err != nil {
+		return completed
+	}
+	for _, m := range metrics {
+		completed[m.Name] = true
+	}
+	return completed
+}
+
+// missingExperiments returns the experiments of list that do not yet have
+// results in runDir.
+func missingExperiments(list []*experiments.Experiment, runDir string) []*experiments.Experiment {
+	completed := completedExperiments(runDir)
+	var missing []*experiments.Experiment
+	for _, e := range list {
+		if !completed[e.Name] {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
+
+// reportToDashboard uploads the metrics of the run described by cfg to the
+// dashboard service running at dashboardURL and flags any metric that
+// regressed compared to the previous run recorded for the same platform.
+func reportToDashboard(cfg *config.Data, dashboardURL, mpiCommit, benchmarksCommit, tag, cluster string, ppn, nnodes int, regressionThreshold float64) error {
+	metrics, err := result.Metrics(getRunDir(cfg))
+	if err != nil {
+		return err
+	}
+
+	var dashboardMetrics []dashboard.Metric
+	for _, m := range metrics {
+		dashboardMetrics = append(dashboardMetrics, dashboard.Metric{
+			Name:    m.Name,
+			MsgSize: m.MsgSize,
+			Value:   m.Value,
+			Unit:    m.Unit,
+		})
+	}
+
+	run := dashboard.Run{
+		Key: dashboard.Key{
+			MPIHash:        mpiCommit,
+			BenchmarksHash: benchmarksCommit,
+			Cluster:        cluster,
+			PPN:            ppn,
+			NumNodes:       nnodes,
+		},
+		Tag:     tag,
+		Metrics: dashboardMetrics,
+	}
+
+	client := dashboard.NewClient(dashboardURL)
+	history, err := client.History(run.Key)
+	if err != nil {
+		log.Printf("unable to fetch dashboard history: %s", err)
+	} else {
+		for _, regression := range dashboard.DetectRegressions(run, history, regressionThreshold) {
+			if regression.MsgSize > 0 {
+				fmt.Printf("REGRESSION: %s (%d bytes) degraded by %.2f%% (%.2f -> %.2f)\n",
+					regression.Metric, regression.MsgSize, regression.PercentChange, regression.Previous, regression.Current)
+				continue
+			}
+			fmt.Printf("REGRESSION: %s degraded by %.2f%% (%.2f -> %.2f)\n",
+				regression.Metric, regression.PercentChange, regression.Previous, regression.Current)
+		}
 	}
+
+	return client.Upload(run)
 }
 
 func main() {
@@ -73,6 +147,16 @@ func main() {
 	ppnFlag := flag.Int("ppn", 1, "Number of MPI ranks per node (default: 1)")
 	nNodesFlag := flag.Int("num-nodes", 1, "Number of nodes to use (default: 1)")
 	longRunFlag := flag.Bool("long", false, "Run all supported tests, including tests not used to create the final metrics")
+	shortRunFlag := flag.Bool("short", false, "Run a reduced message-size range and iteration count so a full sanity run completes in a few minutes")
+	dashboardURLFlag := flag.String("dashboard-url", "", "URL of a regression tracking dashboard service to upload results to (optional)")
+	commitFlag := flag.String("commit", "", "Git hash of the MPI implementation being benchmarked, reported to the dashboard service (optional)")
+	benchmarksCommitFlag := flag.String("benchmarks-commit", "", "Git hash of the benchmark suites being run, reported to the dashboard service (optional)")
+	tagFlag := flag.String("tag", "", "User-defined label for this run, reported to the dashboard service (optional)")
+	regressionThresholdFlag := flag.Float64("regression-threshold", dashboard.DefaultRegressionThreshold, "Percentage of metric degradation above which a regression is flagged when reporting to the dashboard service")
+	containerFlag := flag.String("container", "", "Container runtime to execute benchmarks with: singularity, podman or docker (optional, disabled by default)")
+	containerImageFlag := flag.String("container-image", "", "Container image to use when -container is set")
+	maxRetriesFlag := flag.Int("max-retries", 0, "Number of times to automatically retry an experiment that did not produce results (default: 0, no retries)")
+	resumeFlag := flag.Bool("resume", false, "Skip experiments that already have results in the run directory from a previous, partial run")
 
 	flag.Parse()
 
@@ -99,6 +183,7 @@ func main() {
 	cfg.Basedir = basedir
 	cfg.BinName = filename
 	cfg.LongRun = *longRunFlag
+	cfg.ShortRun = *shortRunFlag
 
 	// Load the configuration
 	err := cfg.Load()
@@ -147,52 +232,28 @@ func main() {
 	exps.Platform.MaxPPR = *ppnFlag
 	exps.Platform.MaxNumNodes = *nNodesFlag
 	exps.MaxExecTime = "1:00:00"
+	if cfg.ShortRun {
+		exps.MaxExecTime = config.ShortModeMaxExecTime
+	}
 
 	// Depending on the execution mode, we want to run either all the installed benchmarks
-	// or only those that are required to compute the final metrics.
+	// or only those the registry marks as required to compute the final metrics.
 	var benchmarksToRun map[string]*benchmark.Install
 	if !cfg.LongRun {
-		// We only keep the installed benchmarks that are part of the list of benchmarks required to generate the final metrics
 		benchmarksToRun = make(map[string]*benchmark.Install)
-
-		var osuBenchmarksToRun []app.Info
-		installedOSUSubBenchmarks := cfg.InstalledBenchmarks["osu"]
-		for _, name := range config.OSURequiredBenchmarks {
-			for _, app := range installedOSUSubBenchmarks.SubBenchmarks {
-				if app.Name == name {
-					osuBenchmarksToRun = append(osuBenchmarksToRun, app)
-					break
+		for suite, installedSuite := range cfg.InstalledBenchmarks {
+			var required []app.Info
+			for _, name := range cfg.Registry.RequiredBenchmarks(suite) {
+				for _, subBenchmark := range installedSuite.SubBenchmarks {
+					if subBenchmark.Name == name {
+						required = append(required, subBenchmark)
+						break
+					}
 				}
 			}
+			benchmarksToRun[suite] = new(benchmark.Install)
+			benchmarksToRun[suite].SubBenchmarks = required
 		}
-		benchmarksToRun["osu"] = new(benchmark.Install)
-		benchmarksToRun["osu"].SubBenchmarks = osuBenchmarksToRun
-
-		var smbBenchmarksToRun []app.Info
-		installedSMBSubBenchmarks := cfg.InstalledBenchmarks["smb"]
-		for _, name := range smb.RequiredBenchmarks {
-			for _, app := range installedSMBSubBenchmarks.SubBenchmarks {
-				if app.Name == name {
-					smbBenchmarksToRun = append(smbBenchmarksToRun, app)
-					break
-				}
-			}
-		}
-		benchmarksToRun["smb"] = new(benchmark.Install)
-		benchmarksToRun["smb"].SubBenchmarks = smbBenchmarksToRun
-
-		var overlapBenchmarksToRun []app.Info
-		installOverlapSubBenchmarks := cfg.InstalledBenchmarks["overlap"]
-		for _, name := range overlap.RequiredBenchmarks {
-			for _, app := range installOverlapSubBenchmarks.SubBenchmarks {
-				if app.Name == name {
-					overlapBenchmarksToRun = append(overlapBenchmarksToRun, app)
-					break
-				}
-			}
-		}
-		benchmarksToRun["overlap"] = new(benchmark.Install)
-		benchmarksToRun["overlap"].SubBenchmarks = overlapBenchmarksToRun
 	} else {
 		benchmarksToRun = cfg.InstalledBenchmarks
 	}
@@ -206,16 +267,59 @@ func main() {
 		}
 	}
 
+	// Make sure the run directory exists and make sure it will be used when running experiments
+	runDir := getRunDir(cfg)
+	if !util.PathExists(runDir) {
+		err = os.MkdirAll(runDir, 0777)
+		if err != nil {
+			fmt.Printf("ERROR: unable to create the run directory: %s", err)
+			os.Exit(1)
+		}
+	}
+
+	alreadyCompleted := completedExperiments(runDir)
+
 	for benchmarkName, installedBenchmark := range benchmarksToRun {
 		for _, subBenchmark := range installedBenchmark.SubBenchmarks {
 			e := new(experiments.Experiment)
 			e.App = new(app.Info)
 			e.App.Name = benchmarkName + "_" + subBenchmark.Name
 			e.App.BinArgs = subBenchmark.BinArgs
+			if override := cfg.Registry.BinArgs(subBenchmark.Name); len(override) > 0 {
+				e.App.BinArgs = override
+			}
+			if cfg.ShortRun {
+				e.App.BinArgs = append(e.App.BinArgs, cfg.Registry.ShortBinArgs(subBenchmark.Name)...)
+			}
 			e.App.BinName = subBenchmark.BinName
 			e.App.BinPath = subBenchmark.BinPath
+			if wrapper := cfg.Registry.RunWrapper(subBenchmark.Name); wrapper != "" {
+				wrapperFields := strings.Fields(wrapper)
+				e.App.BinArgs = append(append(wrapperFields[1:], e.App.BinName), e.App.BinArgs...)
+				e.App.BinName = wrapperFields[0]
+			}
+			if *containerFlag != "" && !cfg.Registry.NotSandboxed(subBenchmark.Name) {
+				wrappedName, wrappedArgs, err := sandbox.Wrap(sandbox.Options{
+					Runtime: sandbox.Runtime(*containerFlag),
+					Image:   *containerImageFlag,
+					RunDir:  getRunDir(cfg),
+					MpiDir:  cfg.WP.MpiDir,
+				}, e.App.BinName, e.App.BinArgs)
+				if err != nil {
+					fmt.Printf("ERROR: unable to containerize %s: %s\n", e.App.Name, err)
+					os.Exit(1)
+				}
+				e.App.BinName = wrappedName
+				e.App.BinArgs = wrappedArgs
+			}
 			e.Name = e.App.Name
-			if experimentIsStrictlyPointToPoint(e.Name) {
+			if *resumeFlag && alreadyCompleted[e.Name] {
+				if *verbose {
+					log.Printf("-> skipping %s, results already present (resume mode)\n", e.Name)
+				}
+				continue
+			}
+			if cfg.Registry.PointToPoint(subBenchmark.Name) {
 				e.Platform = new(platform.Info)
 				e.Platform.Name = exps.Platform.Name
 				e.Platform.Device = exps.Platform.Device
@@ -227,30 +331,93 @@ func main() {
 		}
 	}
 
-	// Make sure the run directory exists and make sure it will be used when running experiments
-	runDir := getRunDir(cfg)
-	if !util.PathExists(runDir) {
-		err = os.MkdirAll(runDir, 0777)
-		if err != nil {
-			fmt.Printf("ERROR: unable to create the run directory: %s", err)
-			os.Exit(1)
-		}
-	}
 	exps.RunDir = runDir
 	exps.ResultsDir = runDir
-	err = exps.Run(r)
-	if err != nil {
-		fmt.Printf("ERROR: unable to execute experiment: %s\n", err)
-		os.Exit(1)
+
+	// Run the experiments, automatically retrying the ones that did not
+	// produce results up to *maxRetriesFlag times.
+	startTime := time.Now()
+	attempts := make(map[string]int)
+	completionTime := make(map[string]time.Time)
+	for _, e := range exps.List {
+		attempts[e.Name] = 0
 	}
+	toRun := exps
+	for attempt := 1; attempt <= *maxRetriesFlag+1; attempt++ {
+		for _, e := range toRun.List {
+			attempts[e.Name]++
+		}
+
+		err = toRun.Run(r)
+		if err != nil {
+			fmt.Printf("ERROR: unable to execute experiment: %s\n", err)
+			os.Exit(1)
+		}
+		toRun.Wait(r)
 
-	exps.Wait(r)
+		nowCompleted := completedExperiments(runDir)
+		for _, e := range toRun.List {
+			if _, done := completionTime[e.Name]; !done && nowCompleted[e.Name] {
+				completionTime[e.Name] = time.Now()
+			}
+		}
+
+		stillMissing := missingExperiments(exps.List, runDir)
+		if len(stillMissing) == 0 || attempt == *maxRetriesFlag+1 {
+			break
+		}
+
+		if *verbose {
+			log.Printf("-> retrying %d experiment(s) that did not produce results (attempt %d/%d)\n", len(stillMissing), attempt+1, *maxRetriesFlag+1)
+		}
+		retry := new(experiments.Experiments)
+		retry.NumResults = exps.NumResults
+		retry.MPICfg = exps.MPICfg
+		retry.Platform = exps.Platform
+		retry.MaxExecTime = exps.MaxExecTime
+		retry.RunDir = exps.RunDir
+		retry.ResultsDir = exps.ResultsDir
+		retry.List = stillMissing
+		toRun = retry
+	}
 	r.Fini()
 	log.Println("-> Job successfully executed")
 
-	err = displayResults(cfg)
+	manifest := result.Manifest{}
+	finalCompleted := completedExperiments(runDir)
+	for _, e := range exps.List {
+		status := result.Failed
+		exitCode := -1
+		duration := time.Since(startTime)
+		if finalCompleted[e.Name] {
+			status = result.Completed
+			exitCode = 0
+			duration = completionTime[e.Name].Sub(startTime)
+		}
+		manifest.Experiments = append(manifest.Experiments, result.ExperimentStatus{
+			Name:     e.Name,
+			Status:   status,
+			Attempts: attempts[e.Name],
+			Duration: duration.String(),
+			ExitCode: exitCode,
+		})
+	}
+	if err := result.WriteManifest(filepath.Join(runDir, result.ManifestFileName), manifest); err != nil {
+		fmt.Printf("ERROR: unable to write the run manifest: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = displayResults(cfg, *commitFlag, exps.Platform.Name, exps.Platform.MaxPPR, exps.Platform.MaxNumNodes)
 	if err != nil {
 		fmt.Printf("ERROR: unable to display results: %s\n", err)
 		os.Exit(1)
 	}
+
+	if *dashboardURLFlag != "" {
+		err = reportToDashboard(cfg, *dashboardURLFlag, *commitFlag, *benchmarksCommitFlag, *tagFlag, exps.Platform.Name, exps.Platform.MaxPPR, exps.Platform.MaxNumNodes, *regressionThresholdFlag)
+		if err != nil {
+			fmt.Printf("ERROR: unable to report results to the dashboard: %s\n", err)
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gvallee/validation_tool/pkg/experiments"
+)
+
+// TestCompletedExperiments verifies that completedExperiments/
+// missingExperiments match an experiment against its results file by the
+// experiment's Name, the same name the "result" package derives from a
+// result file's basename.
+func TestCompletedExperiments(t *testing.T) {
+	runDir, err := ioutil.TempDir("", "openhpca_run_test")
+	if err != nil {
+		t.Fatalf("unable to create temporary run directory: %s", err)
+	}
+	defer os.RemoveAll(runDir)
+
+	if err := ioutil.WriteFile(filepath.Join(runDir, "osu_osu_latency.txt"), []byte("1.23 us\n"), 0644); err != nil {
+		t.Fatalf("unable to write result file: %s", err)
+	}
+
+	list := []*experiments.Experiment{
+		{Name: "osu_osu_latency"},
+		{Name: "osu_osu_bw"},
+	}
+
+	completed := completedExperiments(runDir)
+	if !completed["osu_osu_latency"] {
+		t.Errorf("expected osu_osu_latency to be reported as completed")
+	}
+	if completed["osu_osu_bw"] {
+		t.Errorf("did not expect osu_osu_bw to be reported as completed")
+	}
+
+	missing := missingExperiments(list, runDir)
+	if len(missing) != 1 || missing[0].Name != "osu_osu_bw" {
+		t.Fatalf("expected only osu_osu_bw to be missing, got %+v", missing)
+	}
+}
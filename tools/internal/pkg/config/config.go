@@ -0,0 +1,124 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+// Package config implements the loading and management of the OpenHPCA
+// configuration, including the detection of the benchmark suites that are
+// installed and ready to be executed.
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/gvallee/go_benchmark/pkg/benchmark"
+	"github.com/gvallee/go_software_build/pkg/app"
+	"github.com/gvallee/go_util/pkg/util"
+)
+
+// ShortModeMaxExecTime is the execution time budget given to a run when
+// short mode is enabled, in place of the regular, much longer budget.
+const ShortModeMaxExecTime = "0:05:00"
+
+// Workspace gathers the directories OpenHPCA uses to install and run benchmarks.
+type Workspace struct {
+	// Basedir is the top directory of the OpenHPCA workspace.
+	Basedir string
+
+	// MpiDir is the directory where the MPI implementation used to run the
+	// benchmarks is installed.
+	MpiDir string
+}
+
+// Data gathers all the configuration required to run the OpenHPCA benchmark suite.
+type Data struct {
+	// Basedir is the directory of the OpenHPCA source tree.
+	Basedir string
+
+	// BinName is the path to the currently running binary.
+	BinName string
+
+	// LongRun specifies whether all the supported benchmarks are executed or
+	// only the ones required to compute the final metrics.
+	LongRun bool
+
+	// ShortRun specifies whether benchmarks are executed with a reduced
+	// message-size range and iteration count, for fast sanity runs.
+	ShortRun bool
+
+	// WP is the workspace used to run the benchmarks.
+	WP *Workspace
+
+	// InstalledBenchmarks is the list of benchmark suites that were detected
+	// on the system, indexed by suite name.
+	InstalledBenchmarks map[string]*benchmark.Install
+
+	// Registry is the set of benchmark entries known to OpenHPCA, loaded
+	// from RegistryFileName (or the built-in defaults if that file does not
+	// exist).
+	Registry *Registry
+}
+
+// Load populates cfg with the on-disk configuration of the OpenHPCA workspace.
+func (cfg *Data) Load() error {
+	if cfg.WP == nil {
+		cfg.WP = new(Workspace)
+		cfg.WP.Basedir = filepath.Join(cfg.Basedir, "workspace")
+		cfg.WP.MpiDir = filepath.Join(cfg.WP.Basedir, "install", "mpi")
+	}
+
+	registry, err := LoadRegistry(filepath.Join(cfg.Basedir, RegistryFileName))
+	if err != nil {
+		return err
+	}
+	cfg.Registry = registry
+
+	return nil
+}
+
+// DetectInstalledBenchmarks scans the workspace and populates
+// cfg.InstalledBenchmarks with the benchmark suites that are ready to run,
+// along with the sub-benchmark binaries found under each suite's bin
+// directory. The suites looked for are osu, smb, overlap and any other
+// suite named in cfg.Registry.
+func (cfg *Data) DetectInstalledBenchmarks() {
+	if cfg.InstalledBenchmarks == nil {
+		cfg.InstalledBenchmarks = make(map[string]*benchmark.Install)
+	}
+	suites := []string{"osu", "smb", "overlap"}
+	if cfg.Registry != nil {
+		seen := map[string]bool{"osu": true, "smb": true, "overlap": true}
+		for _, entry := range cfg.Registry.Benchmarks {
+			if entry.Suite != "" && !seen[entry.Suite] {
+				seen[entry.Suite] = true
+				suites = append(suites, entry.Suite)
+			}
+		}
+	}
+	for _, suite := range suites {
+		binDir := filepath.Join(cfg.WP.Basedir, "install", suite, "bin")
+		if !util.PathExists(binDir) {
+			continue
+		}
+
+		entries, err := ioutil.ReadDir(binDir)
+		if err != nil {
+			continue
+		}
+
+		install := new(benchmark.Install)
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			install.SubBenchmarks = append(install.SubBenchmarks, app.Info{
+				Name:    entry.Name(),
+				BinName: entry.Name(),
+				BinPath: binDir,
+			})
+		}
+		cfg.InstalledBenchmarks[suite] = install
+	}
+}
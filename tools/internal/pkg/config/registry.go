@@ -0,0 +1,166 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gvallee/go_util/pkg/util"
+)
+
+// RegistryFileName is the name of the TOML file, relative to the OpenHPCA
+// source tree, describing the benchmark registry.
+const RegistryFileName = "etc/benchmarks.toml"
+
+// BenchmarkEntry describes a single sub-benchmark known to OpenHPCA: the
+// suite it belongs to, how it must be executed, and its default arguments.
+type BenchmarkEntry struct {
+	// Suite is the name of the benchmark suite the entry belongs to (e.g.,
+	// "osu", "smb", "overlap").
+	Suite string `toml:"suite"`
+
+	// Name is the name of the sub-benchmark within the suite.
+	Name string `toml:"name"`
+
+	// PointToPoint specifies whether the sub-benchmark is strictly a
+	// point-to-point benchmark, which forces ppn=1 and nnodes=2.
+	PointToPoint bool `toml:"point_to_point"`
+
+	// Required specifies whether the sub-benchmark is part of the set used
+	// to compute OpenHPCA's final metrics (i.e., it is run even when not in
+	// long mode).
+	Required bool `toml:"required"`
+
+	// BinArgs, when set, overrides the installed sub-benchmark's default
+	// command-line arguments entirely.
+	BinArgs []string `toml:"bin_args"`
+
+	// ShortBinArgs, when set, is appended to the sub-benchmark's arguments
+	// when short mode is enabled, to reduce its message-size range and
+	// iteration count.
+	ShortBinArgs []string `toml:"short_bin_args"`
+
+	// RunWrapper, when set, is a command prepended to the sub-benchmark's
+	// invocation (e.g., a container runtime or a profiling wrapper).
+	RunWrapper string `toml:"run_wrapper"`
+
+	// NotSandboxed specifies that the sub-benchmark cannot be executed
+	// inside a container runtime (e.g., because it relies on a host-level
+	// MPI launcher) and must always run directly on the host.
+	NotSandboxed bool `toml:"not_sandboxed"`
+}
+
+// Registry is the set of benchmark entries known to OpenHPCA, normally
+// loaded from RegistryFileName.
+type Registry struct {
+	Benchmarks []BenchmarkEntry `toml:"benchmark"`
+}
+
+// defaultRegistry is used when no registry file is found on disk, so
+// OpenHPCA keeps working out of the box.
+func defaultRegistry() *Registry {
+	return &Registry{
+		Benchmarks: []BenchmarkEntry{
+			{Suite: "osu", Name: "osu_latency", PointToPoint: true, Required: true, ShortBinArgs: []string{"-i", "100", "-x", "10", "-m", "1:1024"}},
+			{Suite: "osu", Name: "osu_noncontig_mem_latency", PointToPoint: true, Required: true, ShortBinArgs: []string{"-i", "100", "-x", "10", "-m", "1:1024"}},
+			{Suite: "osu", Name: "osu_bw", PointToPoint: true, Required: true, ShortBinArgs: []string{"-i", "100", "-x", "10", "-m", "1:1024"}},
+			{Suite: "osu", Name: "osu_noncontig_mem_bw", PointToPoint: true, Required: true, ShortBinArgs: []string{"-i", "100", "-x", "10", "-m", "1:1024"}},
+			{Suite: "smb", Name: "smb_mpi_overhead", PointToPoint: true, Required: true, ShortBinArgs: []string{"-n", "100"}},
+			{Suite: "overlap", Name: "overlap", PointToPoint: false, Required: true, ShortBinArgs: []string{"-n", "100"}},
+		},
+	}
+}
+
+// LoadRegistry loads the benchmark registry from path. If path does not
+// exist, the built-in default registry is returned instead.
+func LoadRegistry(path string) (*Registry, error) {
+	if !util.PathExists(path) {
+		return defaultRegistry(), nil
+	}
+
+	registry := new(Registry)
+	if _, err := toml.DecodeFile(path, registry); err != nil {
+		return nil, fmt.Errorf("unable to parse benchmark registry %s: %w", path, err)
+	}
+
+	return registry, nil
+}
+
+// find returns the entry describing name, or nil if name is not known to
+// the registry.
+func (reg *Registry) find(name string) *BenchmarkEntry {
+	if reg == nil {
+		return nil
+	}
+	for i := range reg.Benchmarks {
+		if reg.Benchmarks[i].Name == name {
+			return &reg.Benchmarks[i]
+		}
+	}
+	return nil
+}
+
+// PointToPoint returns true if name is registered as a strictly
+// point-to-point benchmark.
+func (reg *Registry) PointToPoint(name string) bool {
+	entry := reg.find(name)
+	return entry != nil && entry.PointToPoint
+}
+
+// RequiredBenchmarks returns the names of the sub-benchmarks of suite that
+// are required to compute OpenHPCA's final set of metrics.
+func (reg *Registry) RequiredBenchmarks(suite string) []string {
+	var names []string
+	if reg == nil {
+		return names
+	}
+	for _, entry := range reg.Benchmarks {
+		if entry.Suite == suite && entry.Required {
+			names = append(names, entry.Name)
+		}
+	}
+	return names
+}
+
+// BinArgs returns the command-line arguments that should replace a
+// sub-benchmark's installed default arguments, or nil if the registry does
+// not override name's arguments.
+func (reg *Registry) BinArgs(name string) []string {
+	entry := reg.find(name)
+	if entry == nil {
+		return nil
+	}
+	return entry.BinArgs
+}
+
+// ShortBinArgs returns the extra command-line arguments to append to name's
+// arguments when short mode is enabled, or nil if none are registered.
+func (reg *Registry) ShortBinArgs(name string) []string {
+	entry := reg.find(name)
+	if entry == nil {
+		return nil
+	}
+	return entry.ShortBinArgs
+}
+
+// RunWrapper returns the command to prepend to name's invocation, or the
+// empty string if none is registered.
+func (reg *Registry) RunWrapper(name string) string {
+	entry := reg.find(name)
+	if entry == nil {
+		return ""
+	}
+	return entry.RunWrapper
+}
+
+// NotSandboxed returns true if name must always run directly on the host
+// and cannot be wrapped by a container runtime.
+func (reg *Registry) NotSandboxed(name string) bool {
+	entry := reg.find(name)
+	return entry != nil && entry.NotSandboxed
+}
@@ -0,0 +1,244 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+// Package dashboard implements the support necessary to upload the metrics
+// of an OpenHPCA run to a regression-tracking dashboard service and to
+// compare a run against the historical results recorded for the same
+// platform, so that performance regressions can be detected automatically.
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRegressionThreshold is the default percentage of degradation, above
+// which a metric is flagged as a regression when no other threshold is
+// provided by the caller.
+const DefaultRegressionThreshold = 5.0
+
+// Key uniquely identifies the platform a run was executed on so that
+// historical results can be compared against one another.
+type Key struct {
+	// MPIHash is the git hash of the MPI implementation that was used.
+	MPIHash string
+
+	// BenchmarksHash is the git hash of the benchmark suites that were used.
+	BenchmarksHash string
+
+	// Cluster is the name of the cluster/partition the run was executed on.
+	Cluster string
+
+	// PPN is the number of MPI ranks per node used during the run.
+	PPN int
+
+	// NumNodes is the number of nodes used during the run.
+	NumNodes int
+}
+
+// Metric is a single data point reported by a benchmark.
+type Metric struct {
+	// Name is the name of the benchmark the metric was computed for.
+	Name string
+
+	// MsgSize is the message size, in bytes, the metric was measured at.
+	// It is 0 for benchmarks that do not vary the message size.
+	MsgSize int
+
+	// Value is the metric's value.
+	Value float64
+
+	// Unit is the unit the value is expressed in (e.g., "us", "MB/s").
+	Unit string
+}
+
+// metricKey identifies a metric within a run, so that two runs can be
+// compared point-by-point rather than just benchmark-by-benchmark.
+type metricKey struct {
+	name    string
+	msgSize int
+}
+
+// Run gathers all the data associated to a single, complete OpenHPCA run.
+type Run struct {
+	Key
+
+	// Tag is an optional, user-defined label identifying the run (e.g., a
+	// release name or a CI build number).
+	Tag string
+
+	// Timestamp is when the run completed, in RFC3339 format.
+	Timestamp string
+
+	// Metrics is the set of per-benchmark metrics gathered during the run.
+	Metrics []Metric
+}
+
+// Regression describes a metric that degraded between two runs on the same
+// platform by more than the configured threshold.
+type Regression struct {
+	// Metric is the name of the benchmark the regression was detected on.
+	Metric string
+
+	// MsgSize is the message size, in bytes, the regression was detected
+	// at. It is 0 for benchmarks that do not vary the message size.
+	MsgSize int
+
+	// Previous is the value recorded for the previous run.
+	Previous float64
+
+	// Current is the value recorded for the current run.
+	Current float64
+
+	// PercentChange is how much the metric degraded, in percent, relative to
+	// Previous. It is always positive: for a lower-is-better unit (e.g.,
+	// latency) it reflects an increase, for a higher-is-better unit (e.g.,
+	// bandwidth) it reflects a decrease.
+	PercentChange float64
+}
+
+// higherIsBetterUnits is the set of units for which a larger value denotes
+// better performance, so that a drop (rather than a rise) is a regression.
+var higherIsBetterUnits = map[string]bool{
+	"mb/s":  true,
+	"gb/s":  true,
+	"b/s":   true,
+	"ops/s": true,
+	"%":     true,
+}
+
+// higherIsBetter returns true if a larger value of a metric expressed in
+// unit denotes better performance (e.g., bandwidth), as opposed to a smaller
+// value being better (e.g., latency).
+func higherIsBetter(unit string) bool {
+	return higherIsBetterUnits[strings.ToLower(strings.TrimSpace(unit))]
+}
+
+// Client is used to interact with a dashboard service reachable at URL.
+type Client struct {
+	// URL is the base URL of the dashboard service.
+	URL string
+}
+
+// NewClient returns a dashboard client targeting the service running at url.
+func NewClient(url string) *Client {
+	return &Client{URL: url}
+}
+
+// Upload sends run to the dashboard service so it is persisted alongside the
+// history of runs executed on the same platform.
+func (c *Client) Upload(run Run) error {
+	if c.URL == "" {
+		return fmt.Errorf("no dashboard URL configured")
+	}
+
+	if run.Timestamp == "" {
+		run.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	payload, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("unable to serialize run: %w", err)
+	}
+
+	resp, err := http.Post(c.URL+"/runs", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to upload run to %s: %w", c.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("dashboard service at %s returned status %s", c.URL, resp.Status)
+	}
+
+	return nil
+}
+
+// History fetches the runs previously recorded by the dashboard service for
+// the platform identified by key, ordered from oldest to most recent.
+func (c *Client) History(key Key) ([]Run, error) {
+	if c.URL == "" {
+		return nil, fmt.Errorf("no dashboard URL configured")
+	}
+
+	payload, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize key: %w", err)
+	}
+
+	resp, err := http.Post(c.URL+"/history", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch history from %s: %w", c.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dashboard service at %s returned status %s", c.URL, resp.Status)
+	}
+
+	var runs []Run
+	if err := json.NewDecoder(resp.Body).Decode(&runs); err != nil {
+		return nil, fmt.Errorf("unable to parse history returned by %s: %w", c.URL, err)
+	}
+
+	return runs, nil
+}
+
+// DetectRegressions compares cur against the most recent of prevRuns that
+// shares cur's platform, and returns the metrics that degraded by more than
+// threshold percent. A threshold of zero or less falls back to
+// DefaultRegressionThreshold.
+func DetectRegressions(cur Run, prevRuns []Run, threshold float64) []Regression {
+	if threshold <= 0 {
+		threshold = DefaultRegressionThreshold
+	}
+
+	var previous *Run
+	for i := len(prevRuns) - 1; i >= 0; i-- {
+		if prevRuns[i].Key == cur.Key {
+			previous = &prevRuns[i]
+			break
+		}
+	}
+	if previous == nil {
+		return nil
+	}
+
+	prevByKey := make(map[metricKey]Metric)
+	for _, m := range previous.Metrics {
+		prevByKey[metricKey{name: m.Name, msgSize: m.MsgSize}] = m
+	}
+
+	var regressions []Regression
+	for _, m := range cur.Metrics {
+		prevMetric, ok := prevByKey[metricKey{name: m.Name, msgSize: m.MsgSize}]
+		if !ok || prevMetric.Value == 0 {
+			continue
+		}
+
+		percentChange := ((m.Value - prevMetric.Value) / prevMetric.Value) * 100
+		degradation := percentChange
+		if higherIsBetter(m.Unit) {
+			degradation = -percentChange
+		}
+
+		if degradation > threshold {
+			regressions = append(regressions, Regression{
+				Metric:        m.Name,
+				MsgSize:       m.MsgSize,
+				Previous:      prevMetric.Value,
+				Current:       m.Value,
+				PercentChange: degradation,
+			})
+		}
+	}
+
+	return regressions
+}
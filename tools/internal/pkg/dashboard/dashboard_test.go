@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+package dashboard
+
+import "testing"
+
+func TestDetectRegressions(t *testing.T) {
+	key := Key{MPIHash: "abc", BenchmarksHash: "def", Cluster: "cluster1", PPN: 1, NumNodes: 2}
+
+	tests := []struct {
+		name      string
+		cur       Run
+		prevRuns  []Run
+		threshold float64
+		want      []Regression
+	}{
+		{
+			name: "latency regression (lower is better)",
+			cur: Run{
+				Key:     key,
+				Metrics: []Metric{{Name: "osu_latency", Value: 11, Unit: "us"}},
+			},
+			prevRuns: []Run{
+				{Key: key, Metrics: []Metric{{Name: "osu_latency", Value: 10, Unit: "us"}}},
+			},
+			threshold: 5,
+			want: []Regression{
+				{Metric: "osu_latency", Previous: 10, Current: 11, PercentChange: 10},
+			},
+		},
+		{
+			name: "bandwidth regression (higher is better)",
+			cur: Run{
+				Key:     key,
+				Metrics: []Metric{{Name: "osu_bw", Value: 900, Unit: "MB/s"}},
+			},
+			prevRuns: []Run{
+				{Key: key, Metrics: []Metric{{Name: "osu_bw", Value: 1000, Unit: "MB/s"}}},
+			},
+			threshold: 5,
+			want: []Regression{
+				{Metric: "osu_bw", Previous: 1000, Current: 900, PercentChange: 10},
+			},
+		},
+		{
+			name: "bandwidth improvement is not a regression",
+			cur: Run{
+				Key:     key,
+				Metrics: []Metric{{Name: "osu_bw", Value: 1100, Unit: "MB/s"}},
+			},
+			prevRuns: []Run{
+				{Key: key, Metrics: []Metric{{Name: "osu_bw", Value: 1000, Unit: "MB/s"}}},
+			},
+			threshold: 5,
+			want:      nil,
+		},
+		{
+			name: "below threshold is not a regression",
+			cur: Run{
+				Key:     key,
+				Metrics: []Metric{{Name: "osu_latency", Value: 10.1, Unit: "us"}},
+			},
+			prevRuns: []Run{
+				{Key: key, Metrics: []Metric{{Name: "osu_latency", Value: 10, Unit: "us"}}},
+			},
+			threshold: 5,
+			want:      nil,
+		},
+		{
+			name: "no previous run for the same platform",
+			cur: Run{
+				Key:     key,
+				Metrics: []Metric{{Name: "osu_latency", Value: 100, Unit: "us"}},
+			},
+			prevRuns: []Run{
+				{Key: Key{Cluster: "other-cluster"}, Metrics: []Metric{{Name: "osu_latency", Value: 10, Unit: "us"}}},
+			},
+			threshold: 5,
+			want:      nil,
+		},
+		{
+			name: "distinct message sizes compared independently",
+			cur: Run{
+				Key: key,
+				Metrics: []Metric{
+					{Name: "osu_latency", MsgSize: 1, Value: 11, Unit: "us"},
+					{Name: "osu_latency", MsgSize: 1024, Value: 100, Unit: "us"},
+				},
+			},
+			prevRuns: []Run{
+				{Key: key, Metrics: []Metric{
+					{Name: "osu_latency", MsgSize: 1, Value: 10, Unit: "us"},
+					{Name: "osu_latency", MsgSize: 1024, Value: 100, Unit: "us"},
+				}},
+			},
+			threshold: 5,
+			want: []Regression{
+				{Metric: "osu_latency", MsgSize: 1, Previous: 10, Current: 11, PercentChange: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectRegressions(tt.cur, tt.prevRuns, tt.threshold)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Metric != tt.want[i].Metric || got[i].MsgSize != tt.want[i].MsgSize ||
+					got[i].Previous != tt.want[i].Previous || got[i].Current != tt.want[i].Current ||
+					got[i].PercentChange != tt.want[i].PercentChange {
+					t.Errorf("got %+v, want %+v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// TxtFileName is the name of the file holding the results in the Go
+	// testing "BenchmarkName-N   iterations   value unit" text format, suitable for
+	// consumption by benchstat.
+	TxtFileName = "results.txt"
+
+	// JSONFileName is the name of the file holding the results in JSON
+	// format, suitable for archival or for parsers such as
+	// golang.org/x/tools/benchmark/parse.
+	JSONFileName = "results.json"
+)
+
+// JSONEntry is a single (benchmark, message size, platform) data point, as
+// written to JSONFileName.
+type JSONEntry struct {
+	// Metric is the name of the benchmark the entry was computed for.
+	Metric string `json:"metric"`
+
+	// MsgSize is the message size, in bytes, the entry was measured at. It
+	// is 0 for benchmarks that do not vary the message size.
+	MsgSize int `json:"msg_size"`
+
+	// Unit is the unit Value is expressed in (e.g., "us", "MB/s").
+	Unit string `json:"unit"`
+
+	// Value is the metric's value.
+	Value float64 `json:"value"`
+
+	// Hash identifies the code (MPI and/or benchmarks) that produced the
+	// result, e.g. a git commit hash.
+	Hash string `json:"hash"`
+
+	// Platform is the name of the cluster/partition the benchmark ran on.
+	Platform string `json:"platform"`
+
+	// PPN is the number of MPI ranks per node used to produce the result.
+	PPN int `json:"ppn"`
+
+	// NumNodes is the number of nodes used to produce the result.
+	NumNodes int `json:"num_nodes"`
+}
+
+// BenchstatString formats metrics using the Go testing benchmark text
+// format ("BenchmarkName-N   iterations   value unit"), one line per metric,
+// with N and the iteration count both written as 1, so the
+// output can be fed directly to benchstat. Metrics measured at a message
+// size have their size folded into the benchmark name, so that benchstat
+// compares matching sizes across runs instead of averaging across them.
+func BenchstatString(metrics []Metric) string {
+	var sb strings.Builder
+	for _, m := range metrics {
+		name := sanitizeBenchmarkName(m.Name)
+		if m.MsgSize > 0 {
+			name = fmt.Sprintf("%s_%d", name, m.MsgSize)
+		}
+		sb.WriteString(fmt.Sprintf("Benchmark%s-1\t1\t%f %s\n", name, m.Value, m.Unit))
+	}
+	return sb.String()
+}
+
+// sanitizeBenchmarkName turns a benchmark's name into a valid Go benchmark
+// function name suffix (Go benchmark names cannot contain spaces or dashes).
+func sanitizeBenchmarkName(name string) string {
+	replacer := strings.NewReplacer("-", "_", " ", "_")
+	return replacer.Replace(name)
+}
+
+// ToJSONEntries turns metrics into the JSON entries written to
+// JSONFileName, tagging each entry with hash, platform, ppn and nnodes.
+func ToJSONEntries(metrics []Metric, hash, platform string, ppn, nnodes int) []JSONEntry {
+	entries := make([]JSONEntry, 0, len(metrics))
+	for _, m := range metrics {
+		entries = append(entries, JSONEntry{
+			Metric:   m.Name,
+			MsgSize:  m.MsgSize,
+			Unit:     m.Unit,
+			Value:    m.Value,
+			Hash:     hash,
+			Platform: platform,
+			PPN:      ppn,
+			NumNodes: nnodes,
+		})
+	}
+	return entries
+}
+
+// WriteBenchstat writes the benchstat-compatible text format of metrics to
+// path.
+func WriteBenchstat(path string, metrics []Metric) error {
+	return ioutil.WriteFile(path, []byte(BenchstatString(metrics)), FilePermission)
+}
+
+// WriteJSON writes metrics, tagged with hash/platform/ppn/nnodes, to path in
+// JSON format.
+func WriteJSON(path string, metrics []Metric, hash, platform string, ppn, nnodes int) error {
+	data, err := json.MarshalIndent(ToJSONEntries(metrics, hash, platform, ppn, nnodes), "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize results to JSON: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, data, FilePermission); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// ManifestFileName is the name of the file recording the status of each
+// experiment of a run, once all its attempts are done. Retries and resuming
+// a partially completed run rely on the result files in the run directory,
+// not on this file.
+const ManifestFileName = "manifest.json"
+
+// Status of a single experiment, as recorded in a run's manifest.
+type Status string
+
+const (
+	// Completed means the experiment ran and its results are available.
+	Completed Status = "completed"
+
+	// Failed means the experiment was attempted but did not produce
+	// results.
+	Failed Status = "failed"
+)
+
+// ExperimentStatus records the outcome of a single experiment of a run.
+type ExperimentStatus struct {
+	// Name is the experiment's name (e.g., "osu_osu_latency").
+	Name string `json:"name"`
+
+	// Status is the outcome of the experiment.
+	Status Status `json:"status"`
+
+	// Attempts is the number of times the experiment was executed,
+	// including retries.
+	Attempts int `json:"attempts"`
+
+	// Duration is how long the experiment took to complete, from the start
+	// of the run to the attempt that produced (or failed to produce) its
+	// results, formatted as a Go duration string (e.g., "1m30s").
+	Duration string `json:"duration"`
+
+	// ExitCode is a best-effort status code for the experiment: 0 if it
+	// completed, -1 if it did not, after exhausting all retries. The
+	// experiments framework does not expose the benchmark process' real
+	// exit code, so this does not distinguish between the various ways an
+	// experiment can fail.
+	ExitCode int `json:"exit_code"`
+}
+
+// Manifest records the status of every experiment of a run.
+type Manifest struct {
+	Experiments []ExperimentStatus `json:"experiments"`
+}
+
+// WriteManifest writes manifest to path in JSON format.
+func WriteManifest(path string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize manifest: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, data, FilePermission); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadManifest reads the manifest stored at path. It returns an empty
+// manifest, not an error, if path does not exist.
+func LoadManifest(path string) (Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Manifest{}, nil
+		}
+		return Manifest{}, fmt.Errorf("unable to read manifest %s: %w", path, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("unable to parse manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
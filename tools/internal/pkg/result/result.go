@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+// Package result implements the parsing and formatting of the results of a
+// complete OpenHPCA run.
+package result
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// FileName is the name of the file in which the human-readable summary
+	// of an OpenHPCA run is stored.
+	FileName = "openhpca_results.txt"
+
+	// FilePermission is the permission used when creating result files.
+	FilePermission = 0644
+)
+
+// Metric is a single (benchmark, message size, value) data point extracted
+// from a run's results directory.
+type Metric struct {
+	// Name is the name of the benchmark the metric was computed for.
+	Name string
+
+	// MsgSize is the message size, in bytes, the metric was measured at.
+	// It is 0 for benchmarks that do not vary the message size (e.g.,
+	// smb_mpi_overhead, overlap).
+	MsgSize int
+
+	// Value is the metric's value.
+	Value float64
+
+	// Unit is the unit the value is expressed in (e.g., "us", "MB/s").
+	Unit string
+}
+
+// Metrics parses the content of runDir and returns the metrics reported by
+// each completed benchmark. Each result file holds one line per data point,
+// with the benchmark's name derived from the file name. A line is either
+// "<value> <unit>", for a benchmark that does not vary the message size, or
+// "<msg size> <value> <unit>", for a benchmark (e.g. a latency or bandwidth
+// test) that reports one value per message size.
+func Metrics(runDir string) ([]Metric, error) {
+	entries, err := ioutil.ReadDir(runDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read run directory %s: %w", runDir, err)
+	}
+
+	var metrics []Metric
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(runDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", entry.Name(), err)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			switch len(fields) {
+			case 2:
+				value, err := strconv.ParseFloat(fields[0], 64)
+				if err != nil {
+					continue
+				}
+				metrics = append(metrics, Metric{
+					Name:  name,
+					Value: value,
+					Unit:  fields[1],
+				})
+			case 3:
+				msgSize, err := strconv.Atoi(fields[0])
+				if err != nil {
+					continue
+				}
+				value, err := strconv.ParseFloat(fields[1], 64)
+				if err != nil {
+					continue
+				}
+				metrics = append(metrics, Metric{
+					Name:    name,
+					MsgSize: msgSize,
+					Value:   value,
+					Unit:    fields[2],
+				})
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// String returns a human-readable summary of all the results found in runDir.
+func String(runDir string) (string, error) {
+	metrics, err := Metrics(runDir)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, m := range metrics {
+		if m.MsgSize > 0 {
+			sb.WriteString(fmt.Sprintf("%s (%d bytes): %f %s\n", m.Name, m.MsgSize, m.Value, m.Unit))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s: %f %s\n", m.Name, m.Value, m.Unit))
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+// Package sandbox implements the support necessary to execute OpenHPCA
+// benchmark binaries inside a container runtime (Singularity, Podman or
+// Docker), so that runs can be reproduced across sites with divergent OS
+// images.
+package sandbox
+
+import "fmt"
+
+// Runtime identifies a container runtime OpenHPCA knows how to wrap a
+// benchmark invocation with.
+type Runtime string
+
+const (
+	// Singularity wraps benchmark invocations with "singularity exec".
+	Singularity Runtime = "singularity"
+
+	// Podman wraps benchmark invocations with "podman run".
+	Podman Runtime = "podman"
+
+	// Docker wraps benchmark invocations with "docker run".
+	Docker Runtime = "docker"
+)
+
+// Options gathers the information required to wrap a benchmark invocation
+// with a container runtime.
+type Options struct {
+	// Runtime is the container runtime to use.
+	Runtime Runtime
+
+	// Image is the container image to run the benchmark with.
+	Image string
+
+	// RunDir is the run directory, bind-mounted into the container so the
+	// benchmark can write its results where OpenHPCA expects them.
+	RunDir string
+
+	// MpiDir is the MPI installation directory, bind-mounted into the
+	// container so the benchmark can find the MPI implementation it was
+	// built against.
+	MpiDir string
+}
+
+// Wrap prepends the container command described by opts to binName/binArgs,
+// so that the resulting command executes binName inside the container
+// runtime instead of directly on the host.
+func Wrap(opts Options, binName string, binArgs []string) (string, []string, error) {
+	if opts.Image == "" {
+		return "", nil, fmt.Errorf("no container image specified")
+	}
+
+	switch opts.Runtime {
+	case Singularity:
+		bind := fmt.Sprintf("%s:%s,%s:%s", opts.RunDir, opts.RunDir, opts.MpiDir, opts.MpiDir)
+		args := append([]string{"exec", "--bind", bind, opts.Image, binName}, binArgs...)
+		return "singularity", args, nil
+	case Podman:
+		args := append([]string{"run", "--rm", "-v", opts.RunDir + ":" + opts.RunDir, "-v", opts.MpiDir + ":" + opts.MpiDir, opts.Image, binName}, binArgs...)
+		return "podman", args, nil
+	case Docker:
+		args := append([]string{"run", "--rm", "-v", opts.RunDir + ":" + opts.RunDir, "-v", opts.MpiDir + ":" + opts.MpiDir, opts.Image, binName}, binArgs...)
+		return "docker", args, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported container runtime: %q", opts.Runtime)
+	}
+}
@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2021, NVIDIA CORPORATION. All rights reserved.
+//
+// See LICENSE.txt for license information
+//
+
+package sandbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	opts := Options{
+		Image:  "openhpca.sif",
+		RunDir: "/run",
+		MpiDir: "/opt/mpi",
+	}
+
+	tests := []struct {
+		name     string
+		runtime  Runtime
+		wantName string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "singularity binds both directories in a single --bind",
+			runtime:  Singularity,
+			wantName: "singularity",
+			wantArgs: []string{"exec", "--bind", "/run:/run,/opt/mpi:/opt/mpi", "openhpca.sif", "osu_latency", "-x", "10"},
+		},
+		{
+			name:     "podman uses one -v flag per mount",
+			runtime:  Podman,
+			wantName: "podman",
+			wantArgs: []string{"run", "--rm", "-v", "/run:/run", "-v", "/opt/mpi:/opt/mpi", "openhpca.sif", "osu_latency", "-x", "10"},
+		},
+		{
+			name:     "docker uses one -v flag per mount",
+			runtime:  Docker,
+			wantName: "docker",
+			wantArgs: []string{"run", "--rm", "-v", "/run:/run", "-v", "/opt/mpi:/opt/mpi", "openhpca.sif", "osu_latency", "-x", "10"},
+		},
+		{
+			name:    "unsupported runtime errors out",
+			runtime: Runtime("lxc"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := opts
+			o.Runtime = tt.runtime
+			gotName, gotArgs, err := Wrap(o, "osu_latency", []string{"-x", "10"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if gotName != tt.wantName {
+				t.Errorf("got command %q, want %q", gotName, tt.wantName)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("got args %+v, want %+v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestWrapRequiresImage(t *testing.T) {
+	_, _, err := Wrap(Options{Runtime: Podman, RunDir: "/run", MpiDir: "/opt/mpi"}, "osu_latency", nil)
+	if err == nil {
+		t.Fatalf("expected an error when no container image is specified")
+	}
+}